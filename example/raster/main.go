@@ -17,7 +17,8 @@ import (
 	"log"
 	"os"
 
-	"code.google.com/p/freetype-go/freetype/raster"
+	"github.com/eaburns/truefont/freetype/geom"
+	"github.com/eaburns/truefont/freetype/raster"
 )
 
 type node struct {
@@ -84,11 +85,11 @@ var inside = []node{
 	{686, 1274, -1},
 }
 
-func p(n node) raster.Point {
+func p(n node) geom.Point {
 	x, y := 20+n.x/4, 380-n.y/4
-	return raster.Point{
-		X: raster.Fix32(x * 256),
-		Y: raster.Fix32(y * 256),
+	return geom.Point{
+		X: geom.Fix32(x * 64),
+		Y: geom.Fix32(y * 64),
 	}
 }
 
@@ -96,19 +97,31 @@ func contour(r *raster.Rasterizer, ns []node) {
 	if len(ns) == 0 {
 		return
 	}
+	// Build a Path from the nodes, then replay it into the rasterizer
+	// through a PathIterator and PathBuilder instead of calling
+	// r.Start/Add1/Add2 directly.
+	var path raster.Path
 	i := 0
-	r.Start(p(ns[i]))
+	path.Start(p(ns[i]))
 	for {
 		switch ns[i].degree {
 		case -1:
 			// -1 signifies end-of-contour.
-			return
+			b := raster.PathBuilder{Adder: r}
+			it := path.Iterate()
+			for {
+				op, pts, ok := it.Next()
+				if !ok {
+					return
+				}
+				b.Add(op, pts)
+			}
 		case 1:
 			i++
-			r.Add1(p(ns[i]))
+			path.Add1(p(ns[i]))
 		case 2:
 			i += 2
-			r.Add2(p(ns[i-1]), p(ns[i]))
+			path.Add2(p(ns[i-1]), p(ns[i]))
 		default:
 			panic("bad degree")
 		}
@@ -118,7 +131,7 @@ func contour(r *raster.Rasterizer, ns []node) {
 func showNodes(m *image.RGBA, ns []node) {
 	for _, n := range ns {
 		p := p(n)
-		x, y := int(p.X)/256, int(p.Y)/256
+		x, y := int(p.X)/64, int(p.Y)/64
 		if !(image.Point{x, y}).In(m.Bounds()) {
 			continue
 		}
@@ -10,32 +10,51 @@ package geom
 import (
 	"fmt"
 	"math"
+
+	"golang.org/x/image/math/fixed"
 )
 
-// A Fix32 is a 24.8 fixed point number.
+// A Fix32 is a 26.6 fixed point number, with 64 units per pixel. This is the
+// same scale as golang.org/x/image/math/fixed.Int26_6, and Fix32 values
+// convert to and from that type with Fixed and FromFixed, so that outlines
+// built with this package can be handed directly to x/image/font consumers.
 type Fix32 int32
 
-// A Fix64 is a 48.16 fixed point number.
+// A Fix64 is a 52.12 fixed point number, the natural result of multiplying
+// two Fix32 values together. It matches the scale of
+// golang.org/x/image/math/fixed.Int52_12.
 type Fix64 int64
 
-// String returns a human-readable representation of a 24.8 fixed point number.
-// For example, the number one-and-a-quarter becomes "1:064".
+// Fixed returns x as a golang.org/x/image/math/fixed.Int26_6.
+func (x Fix32) Fixed() fixed.Int26_6 { return fixed.Int26_6(x) }
+
+// FromFixed returns x as a Fix32.
+func FromFixed(x fixed.Int26_6) Fix32 { return Fix32(x) }
+
+// Fixed returns x as a golang.org/x/image/math/fixed.Int52_12.
+func (x Fix64) Fixed() fixed.Int52_12 { return fixed.Int52_12(x) }
+
+// FromFixed64 returns x as a Fix64.
+func FromFixed64(x fixed.Int52_12) Fix64 { return Fix64(x) }
+
+// String returns a human-readable representation of a 26.6 fixed point
+// number. For example, the number one-and-a-quarter becomes "1:16".
 func (x Fix32) String() string {
 	if x < 0 {
 		x = -x
-		return fmt.Sprintf("-%d:%03d", int32(x/256), int32(x%256))
+		return fmt.Sprintf("-%d:%02d", int32(x/64), int32(x%64))
 	}
-	return fmt.Sprintf("%d:%03d", int32(x/256), int32(x%256))
+	return fmt.Sprintf("%d:%02d", int32(x/64), int32(x%64))
 }
 
-// String returns a human-readable representation of a 48.16 fixed point number.
-// For example, the number one-and-a-quarter becomes "1:16384".
+// String returns a human-readable representation of a 52.12 fixed point
+// number. For example, the number one-and-a-quarter becomes "1:1024".
 func (x Fix64) String() string {
 	if x < 0 {
 		x = -x
-		return fmt.Sprintf("-%d:%05d", int64(x/65536), int64(x%65536))
+		return fmt.Sprintf("-%d:%04d", int64(x/4096), int64(x%4096))
 	}
-	return fmt.Sprintf("%d:%05d", int64(x/65536), int64(x%65536))
+	return fmt.Sprintf("%d:%04d", int64(x/4096), int64(x%4096))
 }
 
 // MaxAbs returns the maximum of abs(a) and abs(b).
@@ -52,7 +71,7 @@ func MaxAbs(a, b Fix32) Fix32 {
 	return a
 }
 
-// A Point represents a two-dimensional point or vector, in 24.8 fixed point
+// A Point represents a two-dimensional point or vector, in 26.6 fixed point
 // format.
 type Point struct {
 	X, Y Fix32
@@ -61,6 +80,16 @@ type Point struct {
 // Pt returns a point with the given x and y coordinates.
 func Pt(x, y Fix32) Point { return Point{x, y} }
 
+// Fixed returns p as a golang.org/x/image/math/fixed.Point26_6.
+func (p Point) Fixed() fixed.Point26_6 {
+	return fixed.Point26_6{X: p.X.Fixed(), Y: p.Y.Fixed()}
+}
+
+// FromFixedPoint returns p as a Point.
+func FromFixedPoint(p fixed.Point26_6) Point {
+	return Point{FromFixed(p.X), FromFixed(p.Y)}
+}
+
 // String returns a human-readable representation of a Point.
 func (p Point) String() string {
 	return "(" + p.X.String() + ", " + p.Y.String() + ")"
@@ -78,7 +107,7 @@ func (p Point) Sub(q Point) Point {
 
 // Mul returns the vector k * p.
 func (p Point) Mul(k Fix32) Point {
-	return Point{p.X * k / 256, p.Y * k / 256}
+	return Point{p.X * k / 64, p.Y * k / 64}
 }
 
 // Neg returns the vector -p, or equivalently p rotated by 180 degrees.
@@ -117,10 +146,12 @@ func (p Point) Norm(length Fix32) Point {
 // Rot45CW returns the vector p rotated clockwise by 45 degrees.
 // Note that the Y-axis grows downwards, so {1, 0}.Rot45CW is {1/√2, 1/√2}.
 func (p Point) Rot45CW() Point {
-	// 181/256 is approximately 1/√2, or sin(π/4).
+	// 11585/16384 is approximately 1/√2, or sin(π/4), good to within
+	// 2^-16. This precision is independent of the Fix32 point scale, so it
+	// stays fixed even as the unit changes.
 	px, py := int64(p.X), int64(p.Y)
-	qx := (+px - py) * 181 / 256
-	qy := (+px + py) * 181 / 256
+	qx := (+px - py) * 11585 / 16384
+	qy := (+px + py) * 11585 / 16384
 	return Point{Fix32(qx), Fix32(qy)}
 }
 
@@ -133,20 +164,24 @@ func (p Point) Rot90CW() Point {
 // Rot135CW returns the vector p rotated clockwise by 135 degrees.
 // Note that the Y-axis grows downwards, so {1, 0}.Rot135CW is {-1/√2, 1/√2}.
 func (p Point) Rot135CW() Point {
-	// 181/256 is approximately 1/√2, or sin(π/4).
+	// 11585/16384 is approximately 1/√2, or sin(π/4), good to within
+	// 2^-16. This precision is independent of the Fix32 point scale, so it
+	// stays fixed even as the unit changes.
 	px, py := int64(p.X), int64(p.Y)
-	qx := (-px - py) * 181 / 256
-	qy := (+px - py) * 181 / 256
+	qx := (-px - py) * 11585 / 16384
+	qy := (+px - py) * 11585 / 16384
 	return Point{Fix32(qx), Fix32(qy)}
 }
 
 // Rot45CCW returns the vector p rotated counter-clockwise by 45 degrees.
 // Note that the Y-axis grows downwards, so {1, 0}.Rot45CCW is {1/√2, -1/√2}.
 func (p Point) Rot45CCW() Point {
-	// 181/256 is approximately 1/√2, or sin(π/4).
+	// 11585/16384 is approximately 1/√2, or sin(π/4), good to within
+	// 2^-16. This precision is independent of the Fix32 point scale, so it
+	// stays fixed even as the unit changes.
 	px, py := int64(p.X), int64(p.Y)
-	qx := (+px + py) * 181 / 256
-	qy := (-px + py) * 181 / 256
+	qx := (+px + py) * 11585 / 16384
+	qy := (-px + py) * 11585 / 16384
 	return Point{Fix32(qx), Fix32(qy)}
 }
 
@@ -159,9 +194,11 @@ func (p Point) Rot90CCW() Point {
 // Rot135CCW returns the vector p rotated counter-clockwise by 135 degrees.
 // Note that the Y-axis grows downwards, so {1, 0}.Rot135CCW is {-1/√2, -1/√2}.
 func (p Point) Rot135CCW() Point {
-	// 181/256 is approximately 1/√2, or sin(π/4).
+	// 11585/16384 is approximately 1/√2, or sin(π/4), good to within
+	// 2^-16. This precision is independent of the Fix32 point scale, so it
+	// stays fixed even as the unit changes.
 	px, py := int64(p.X), int64(p.Y)
-	qx := (-px + py) * 181 / 256
-	qy := (-px - py) * 181 / 256
+	qx := (-px + py) * 11585 / 16384
+	qy := (-px - py) * 11585 / 16384
 	return Point{Fix32(qx), Fix32(qy)}
 }
@@ -0,0 +1,201 @@
+// Copyright 2010 The Freetype-Go Authors. All rights reserved.
+// Use of this source code is governed by your choice of either the
+// FreeType License or the GNU General Public License version 2 (or
+// any later version), both of which can be found in the LICENSE file.
+
+package raster
+
+import (
+	"github.com/eaburns/truefont/freetype/geom"
+)
+
+// dashFlattenTolerance is the flatness tolerance used to approximate the
+// arc length of a curve segment for dashing purposes. It reuses the same
+// test as Flatten, so a curve is subdivided only as finely as the dash
+// walk needs to place its on/off boundaries accurately.
+const dashFlattenTolerance = geom.Fix32(4)
+
+// pointCollector is an Adder that records each Add1 point, used to turn a
+// flattened curve into a polyline for the dasher to walk.
+type pointCollector struct {
+	pts []geom.Point
+}
+
+func (c *pointCollector) Start(a geom.Point)      {}
+func (c *pointCollector) Add1(b geom.Point)       { c.pts = append(c.pts, b) }
+func (c *pointCollector) Add2(b, d geom.Point)    { panic("freetype/raster: unreachable") }
+func (c *pointCollector) Add3(b, d, e geom.Point) { panic("freetype/raster: unreachable") }
+
+// flattenQuadPoints returns the polyline approximating the quadratic Bézier
+// p0, p1, p2 to within tolerance.
+func flattenQuadPoints(p0, p1, p2 geom.Point, tolerance geom.Fix32) []geom.Point {
+	var c pointCollector
+	flattenQuad(&c, p0, p1, p2, tolerance, 0)
+	return c.pts
+}
+
+// flattenCubicPoints returns the polyline approximating the cubic Bézier
+// p0, p1, p2, p3 to within tolerance.
+func flattenCubicPoints(p0, p1, p2, p3 geom.Point, tolerance geom.Fix32) []geom.Point {
+	var c pointCollector
+	flattenCubic(&c, p0, p1, p2, p3, tolerance, 0)
+	return c.pts
+}
+
+// dasher walks a sequence of line segments, splitting them at the
+// boundaries of a dash pattern and emitting the on-portions to dst.
+type dasher struct {
+	dst    *Path
+	dash   []geom.Fix32
+	index  int
+	on     bool
+	remain geom.Fix32
+	open   bool // whether dst has an unclosed Start for the current on-run.
+	pos    geom.Point
+}
+
+// resetPattern rewinds the dash state to the start of the pattern, advanced
+// by offset. It is called at the start of every subpath, since dash state
+// does not persist across a Start.
+func (d *dasher) resetPattern(offset geom.Fix32) {
+	d.index, d.on, d.open = 0, true, false
+	remain := d.dash[0]
+	for offset > 0 {
+		if offset < remain {
+			remain -= offset
+			break
+		}
+		offset -= remain
+		d.nextDash()
+		remain = d.remain
+	}
+	d.remain = remain
+}
+
+// startSubpath begins a new subpath of the path being dashed at a.
+func (d *dasher) startSubpath(a geom.Point, offset geom.Fix32) {
+	d.resetPattern(offset)
+	d.pos = a
+	if d.on {
+		d.dst.Start(a)
+		d.open = true
+	}
+}
+
+// lineTo walks the line segment from d.pos to b, emitting the on-portions
+// to dst and splitting the segment at every dash boundary it crosses.
+func (d *dasher) lineTo(b geom.Point) {
+	for {
+		for d.remain == 0 {
+			d.nextDash()
+		}
+		length := b.Sub(d.pos).Len()
+		if length == 0 {
+			return
+		}
+		if d.remain >= length {
+			d.remain -= length
+			d.advanceTo(b)
+			return
+		}
+		// The dash boundary falls strictly inside (d.pos, b); split the
+		// segment at the exact parameter where the cumulative length
+		// equals the boundary. This is computed as a single multiply
+		// followed by a single divide per axis, rather than via an
+		// intermediate Fix32 fraction (which truncates twice): with d.remain
+		// much smaller than length, two truncating divisions can round the
+		// step to zero and leave d.pos stuck forever. A single division can
+		// still give a zero step on both axes of a diagonal segment when
+		// d.remain is one unit; the split then moves one unit along the
+		// longer axis instead.
+		delta := b.Sub(d.pos)
+		sx := int64(delta.X) * int64(d.remain) / int64(length)
+		sy := int64(delta.Y) * int64(d.remain) / int64(length)
+		if sx == 0 && sy == 0 {
+			m := geom.MaxAbs(delta.X, delta.Y)
+			switch {
+			case delta.X == m:
+				sx = 1
+			case delta.X == -m:
+				sx = -1
+			case delta.Y == m:
+				sy = 1
+			default:
+				sy = -1
+			}
+		}
+		split := geom.Pt(d.pos.X+geom.Fix32(sx), d.pos.Y+geom.Fix32(sy))
+		d.advanceTo(split)
+		d.nextDash()
+	}
+}
+
+// advanceTo moves the pen to pt, extending the current on-run if the dash
+// is currently on.
+func (d *dasher) advanceTo(pt geom.Point) {
+	if d.on {
+		if !d.open {
+			d.dst.Start(d.pos)
+			d.open = true
+		}
+		d.dst.Add1(pt)
+	}
+	d.pos = pt
+}
+
+// nextDash advances to the next entry in the dash pattern, toggling on/off
+// and closing the current on-run so that the next advanceTo starts a fresh
+// subpath in dst.
+func (d *dasher) nextDash() {
+	d.index = (d.index + 1) % len(d.dash)
+	d.on = !d.on
+	d.remain = d.dash[d.index]
+	d.open = false
+}
+
+// dashPath walks src, applying the dash pattern dash (with a starting
+// offset of dashOffset) and appending only the on-portions to dst. Curves
+// are subdivided to dashFlattenTolerance and their resulting line segments
+// are dashed individually, so that dash state persists across a subpath's
+// segments but resets at each Start.
+func dashPath(dst *Path, src Path, dash []geom.Fix32, dashOffset geom.Fix32) {
+	if !hasPositiveDash(dash) {
+		dst.AddPath(src)
+		return
+	}
+	d := &dasher{dst: dst, dash: dash}
+	it := src.Iterate()
+	for {
+		op, pts, ok := it.Next()
+		if !ok {
+			return
+		}
+		switch op {
+		case OpStart:
+			d.startSubpath(pts[0], dashOffset)
+		case OpLine:
+			d.lineTo(pts[0])
+		case OpQuad:
+			for _, pt := range flattenQuadPoints(d.pos, pts[0], pts[1], dashFlattenTolerance) {
+				d.lineTo(pt)
+			}
+		case OpCubic:
+			for _, pt := range flattenCubicPoints(d.pos, pts[0], pts[1], pts[2], dashFlattenTolerance) {
+				d.lineTo(pt)
+			}
+		}
+	}
+}
+
+// hasPositiveDash reports whether dash contains at least one positive
+// entry. A pattern that is empty or all zeros can never advance along the
+// path, so dashPath treats it as "no dashing" instead of toggling between
+// zero-length on and off runs forever.
+func hasPositiveDash(dash []geom.Fix32) bool {
+	for _, d := range dash {
+		if d > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+// Copyright 2010 The Freetype-Go Authors. All rights reserved.
+// Use of this source code is governed by your choice of either the
+// FreeType License or the GNU General Public License version 2 (or
+// any later version), both of which can be found in the LICENSE file.
+
+package raster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eaburns/truefont/freetype/geom"
+)
+
+// runDashPath calls dashPath in a goroutine and fails the test instead of
+// hanging if it doesn't return: a dash pattern with an entry much smaller
+// than the path it's applied to used to make dashPath spin forever.
+func runDashPath(t *testing.T, src Path, dash []geom.Fix32, offset geom.Fix32) Path {
+	t.Helper()
+	done := make(chan Path, 1)
+	go func() {
+		var dst Path
+		dashPath(&dst, src, dash, offset)
+		done <- dst
+	}()
+	select {
+	case dst := <-done:
+		return dst
+	case <-time.After(2 * time.Second):
+		t.Fatal("dashPath did not return within 2s")
+		return nil
+	}
+}
+
+func TestDashPathTinyEntryDoesNotHang(t *testing.T) {
+	var src Path
+	src.Start(geom.Pt(0, 0))
+	src.Add1(geom.Pt(100*64, 0))
+
+	// The off-run (10 Fix32 units, about 0.16px) is tiny relative to the
+	// 100px line, which used to truncate the split fraction to zero and
+	// loop forever without making progress.
+	runDashPath(t, src, []geom.Fix32{0, 10}, 0)
+}
+
+func TestDashPathAllZeroPatternIsNoOp(t *testing.T) {
+	var src Path
+	src.Start(geom.Pt(0, 0))
+	src.Add1(geom.Pt(100*64, 0))
+
+	dst := runDashPath(t, src, []geom.Fix32{0, 0}, 0)
+	if len(dst) != len(src) {
+		t.Fatalf("got a %d-element path, want the %d-element src unchanged", len(dst), len(src))
+	}
+	for i := range src {
+		if dst[i] != src[i] {
+			t.Fatalf("dst[%d] = %v, want %v", i, dst[i], src[i])
+		}
+	}
+}
+
+func TestDashPathSplitsAtBoundary(t *testing.T) {
+	var src Path
+	src.Start(geom.Pt(0, 0))
+	src.Add1(geom.Pt(100*64, 0))
+
+	dst := runDashPath(t, src, []geom.Fix32{20 * 64, 20 * 64}, 0)
+	if len(dst) == 0 {
+		t.Fatal("got an empty dst, want at least one on-run")
+	}
+	// The first on-run should start at the origin and end at x=20px.
+	it := dst.Iterate()
+	op, pts, ok := it.Next()
+	if !ok || op != OpStart || pts[0] != geom.Pt(0, 0) {
+		t.Fatalf("first op = %v %v %v, want Start at (0,0)", op, pts, ok)
+	}
+	op, pts, ok = it.Next()
+	if !ok || op != OpLine || pts[0] != geom.Pt(20*64, 0) {
+		t.Fatalf("second op = %v %v %v, want Line to (20px,0)", op, pts, ok)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2010 The Freetype-Go Authors. All rights reserved.
+// Use of this source code is governed by your choice of either the
+// FreeType License or the GNU General Public License version 2 (or
+// any later version), both of which can be found in the LICENSE file.
+
+package raster
+
+import (
+	"github.com/eaburns/truefont/freetype/geom"
+)
+
+// maxFlattenDepth bounds the recursion of Flatten, so that a degenerate
+// curve (e.g. one whose control points never converge) still terminates.
+const maxFlattenDepth = 32
+
+// Flatten replays p to dst, approximating each quadratic and cubic segment
+// with a sequence of Add1 calls such that no point on the flattened line is
+// further than tolerance from the original curve. Start and Add1 segments
+// are passed through unchanged. This lets a caller flatten an outline once
+// and rasterize it many times, or feed it to anything that only understands
+// straight lines, e.g. p.Flatten(rasterizer, tolerance).
+func (p Path) Flatten(dst Adder, tolerance geom.Fix32) {
+	var cur geom.Point
+	it := p.Iterate()
+	for {
+		op, pts, ok := it.Next()
+		if !ok {
+			return
+		}
+		switch op {
+		case OpStart:
+			cur = pts[0]
+			dst.Start(cur)
+		case OpLine:
+			cur = pts[0]
+			dst.Add1(cur)
+		case OpQuad:
+			flattenQuad(dst, cur, pts[0], pts[1], tolerance, 0)
+			cur = pts[1]
+		case OpCubic:
+			flattenCubic(dst, cur, pts[0], pts[1], pts[2], tolerance, 0)
+			cur = pts[2]
+		}
+	}
+}
+
+// mid returns the midpoint of a and b.
+func mid(a, b geom.Point) geom.Point {
+	return geom.Pt((a.X+b.X)/2, (a.Y+b.Y)/2)
+}
+
+// flatEnough reports whether pt lies within tolerance of the chord a-b. It
+// compares squared distances so that no square root is needed: the
+// perpendicular distance from pt to the line a-b is
+// |(b-a) × (pt-a)| / |b-a|, so distance <= tolerance iff
+// cross² <= tolerance² * |b-a|².
+//
+// The squaring is done in float64, not int64: for a chord spanning a few
+// thousand pixels, cross can already exceed about 2^31.5, and squaring that
+// in int64 overflows and wraps negative, which would make flatEnough report
+// "flat enough" for a curve that manifestly isn't.
+func flatEnough(pt, a, b geom.Point, tolerance geom.Fix32) bool {
+	ux, uy := float64(b.X-a.X), float64(b.Y-a.Y)
+	vx, vy := float64(pt.X-a.X), float64(pt.Y-a.Y)
+	cross := ux*vy - uy*vx
+	tol2 := float64(tolerance) * float64(tolerance)
+	chord2 := ux*ux + uy*uy
+	if chord2 == 0 {
+		// a and b coincide; fall back to the distance from a to pt.
+		return vx*vx+vy*vy <= tol2
+	}
+	return cross*cross <= tol2*chord2
+}
+
+// flattenQuad emits Add1 segments to dst approximating the quadratic Bézier
+// p0, p1, p2 to within tolerance, using recursive de Casteljau subdivision.
+func flattenQuad(dst Adder, p0, p1, p2 geom.Point, tolerance geom.Fix32, depth int) {
+	if depth >= maxFlattenDepth || flatEnough(p1, p0, p2, tolerance) {
+		dst.Add1(p2)
+		return
+	}
+	m01 := mid(p0, p1)
+	m12 := mid(p1, p2)
+	m := mid(m01, m12)
+	flattenQuad(dst, p0, m01, m, tolerance, depth+1)
+	flattenQuad(dst, m, m12, p2, tolerance, depth+1)
+}
+
+// flattenCubic emits Add1 segments to dst approximating the cubic Bézier
+// p0, p1, p2, p3 to within tolerance, using recursive de Casteljau
+// subdivision.
+func flattenCubic(dst Adder, p0, p1, p2, p3 geom.Point, tolerance geom.Fix32, depth int) {
+	if depth >= maxFlattenDepth || (flatEnough(p1, p0, p3, tolerance) && flatEnough(p2, p0, p3, tolerance)) {
+		dst.Add1(p3)
+		return
+	}
+	p01 := mid(p0, p1)
+	p12 := mid(p1, p2)
+	p23 := mid(p2, p3)
+	p012 := mid(p01, p12)
+	p123 := mid(p12, p23)
+	p0123 := mid(p012, p123)
+	flattenCubic(dst, p0, p01, p012, p0123, tolerance, depth+1)
+	flattenCubic(dst, p0123, p123, p23, p3, tolerance, depth+1)
+}
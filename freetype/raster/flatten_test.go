@@ -0,0 +1,67 @@
+// Copyright 2010 The Freetype-Go Authors. All rights reserved.
+// Use of this source code is governed by your choice of either the
+// FreeType License or the GNU General Public License version 2 (or
+// any later version), both of which can be found in the LICENSE file.
+
+package raster
+
+import (
+	"testing"
+
+	"github.com/eaburns/truefont/freetype/geom"
+)
+
+// countingAdder counts Add1 calls, to check how finely Flatten subdivides a
+// curve without caring about the exact points it emits.
+type countingAdder struct {
+	n int
+}
+
+func (c *countingAdder) Start(a geom.Point)      {}
+func (c *countingAdder) Add1(b geom.Point)       { c.n++ }
+func (c *countingAdder) Add2(b, d geom.Point)    { panic("unreachable") }
+func (c *countingAdder) Add3(b, d, e geom.Point) { panic("unreachable") }
+
+func TestFlattenQuadLargeChordDoesNotOverflow(t *testing.T) {
+	// A chord spanning 10,000 pixels with a 500 pixel bow: at 1 pixel
+	// tolerance this must still subdivide into many segments. Before the
+	// flatEnough fix, the int64 cross product for this size overflowed and
+	// wrapped negative, which made flattenQuad stop after a single segment.
+	const px = geom.Fix32(64)
+	p0 := geom.Pt(0, 0)
+	p1 := geom.Pt(5000*px, 500*px)
+	p2 := geom.Pt(10000*px, 0)
+
+	var c countingAdder
+	flattenQuad(&c, p0, p1, p2, 1*px, 0)
+	if c.n < 8 {
+		t.Errorf("got %d segments for a large quadratic, want at least 8", c.n)
+	}
+}
+
+func TestFlattenQuadSmallChordSubdivides(t *testing.T) {
+	// The same shape at 1/10th scale, at the same 1 pixel tolerance, must
+	// still be subdivided into several segments, not emitted as one chord.
+	const px = geom.Fix32(64)
+	p0 := geom.Pt(0, 0)
+	p1 := geom.Pt(500*px, 50*px)
+	p2 := geom.Pt(1000*px, 0)
+
+	var c countingAdder
+	flattenQuad(&c, p0, p1, p2, 1*px, 0)
+	if c.n < 4 {
+		t.Errorf("got %d segments for a small quadratic, want at least 4", c.n)
+	}
+}
+
+func TestFlattenQuadStraightLineEmitsOneSegment(t *testing.T) {
+	p0 := geom.Pt(0, 0)
+	p1 := geom.Pt(32, 0)
+	p2 := geom.Pt(64, 0)
+
+	var c countingAdder
+	flattenQuad(&c, p0, p1, p2, 1, 0)
+	if c.n != 1 {
+		t.Errorf("got %d segments for a straight quadratic, want 1", c.n)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2010 The Freetype-Go Authors. All rights reserved.
+// Use of this source code is governed by your choice of either the
+// FreeType License or the GNU General Public License version 2 (or
+// any later version), both of which can be found in the LICENSE file.
+
+package raster
+
+import (
+	"github.com/eaburns/truefont/freetype/geom"
+)
+
+// An Op identifies the kind of segment a PathIterator yields.
+type Op int
+
+const (
+	// OpStart starts a new curve at pts[0].
+	OpStart Op = iota
+	// OpLine adds a linear segment ending at pts[0].
+	OpLine
+	// OpQuad adds a quadratic segment with control point pts[0] and end
+	// point pts[1].
+	OpQuad
+	// OpCubic adds a cubic segment with control points pts[0], pts[1] and
+	// end point pts[2].
+	OpCubic
+)
+
+// A PathIterator walks the segments of a Path, decoding its []geom.Fix32
+// tape once so that callers don't each have to hand-roll the same index
+// arithmetic.
+type PathIterator struct {
+	p Path
+	i int
+}
+
+// Iterate returns a PathIterator over p.
+func (p Path) Iterate() *PathIterator {
+	return &PathIterator{p: p}
+}
+
+// Next returns the next segment in the iteration. ok is false once the
+// iteration is complete, at which point op and pts are unspecified. Only
+// the leading elements of pts used by op are meaningful; see the Op
+// constants.
+func (it *PathIterator) Next() (op Op, pts [3]geom.Point, ok bool) {
+	if it.i >= len(it.p) {
+		return 0, pts, false
+	}
+	p := it.p
+	switch p[it.i] {
+	case 0:
+		pts[0] = geom.Pt(p[it.i+1], p[it.i+2])
+		op = OpStart
+		it.i += 4
+	case 1:
+		pts[0] = geom.Pt(p[it.i+1], p[it.i+2])
+		op = OpLine
+		it.i += 4
+	case 2:
+		pts[0] = geom.Pt(p[it.i+1], p[it.i+2])
+		pts[1] = geom.Pt(p[it.i+3], p[it.i+4])
+		op = OpQuad
+		it.i += 6
+	case 3:
+		pts[0] = geom.Pt(p[it.i+1], p[it.i+2])
+		pts[1] = geom.Pt(p[it.i+3], p[it.i+4])
+		pts[2] = geom.Pt(p[it.i+5], p[it.i+6])
+		op = OpCubic
+		it.i += 8
+	default:
+		panic("freetype/raster: bad path")
+	}
+	return op, pts, true
+}
+
+// A PathBuilder replays the (Op, pts) pairs yielded by a PathIterator into
+// an Adder, so that code holding only that generic form can still drive any
+// Start/Add1/Add2/Add3 consumer.
+type PathBuilder struct {
+	Adder
+}
+
+// Add replays a single segment, as yielded by PathIterator.Next, to the
+// wrapped Adder.
+func (b PathBuilder) Add(op Op, pts [3]geom.Point) {
+	switch op {
+	case OpStart:
+		b.Start(pts[0])
+	case OpLine:
+		b.Add1(pts[0])
+	case OpQuad:
+		b.Add2(pts[0], pts[1])
+	case OpCubic:
+		b.Add3(pts[0], pts[1], pts[2])
+	}
+}
@@ -30,25 +30,24 @@ type Path []geom.Fix32
 // String returns a human-readable representation of a Path.
 func (p Path) String() string {
 	s := ""
-	for i := 0; i < len(p); {
-		if i != 0 {
+	it := p.Iterate()
+	for first := true; ; first = false {
+		op, pts, ok := it.Next()
+		if !ok {
+			break
+		}
+		if !first {
 			s += " "
 		}
-		switch p[i] {
-		case 0:
-			s += "S0" + fmt.Sprint([]geom.Fix32(p[i+1:i+3]))
-			i += 4
-		case 1:
-			s += "A1" + fmt.Sprint([]geom.Fix32(p[i+1:i+3]))
-			i += 4
-		case 2:
-			s += "A2" + fmt.Sprint([]geom.Fix32(p[i+1:i+5]))
-			i += 6
-		case 3:
-			s += "A3" + fmt.Sprint([]geom.Fix32(p[i+1:i+7]))
-			i += 8
-		default:
-			panic("freetype/raster: bad path")
+		switch op {
+		case OpStart:
+			s += "S0" + fmt.Sprint([]geom.Fix32{pts[0].X, pts[0].Y})
+		case OpLine:
+			s += "A1" + fmt.Sprint([]geom.Fix32{pts[0].X, pts[0].Y})
+		case OpQuad:
+			s += "A2" + fmt.Sprint([]geom.Fix32{pts[0].X, pts[0].Y, pts[1].X, pts[1].Y})
+		case OpCubic:
+			s += "A3" + fmt.Sprint([]geom.Fix32{pts[0].X, pts[0].Y, pts[1].X, pts[1].Y, pts[2].X, pts[2].Y})
 		}
 	}
 	return s
@@ -129,6 +128,16 @@ func (p *Path) AddStroke(q Path, width geom.Fix32, cr Capper, jr Joiner) {
 	Stroke(p, q, width, cr, jr)
 }
 
+// AddDashedStroke adds a dashed and stroked Path. dash gives the lengths of
+// alternating on and off runs, starting on, and dashOffset shifts where the
+// pattern begins along q; dash state resets at the start of each subpath of
+// q. Existing callers of AddStroke are unaffected.
+func (p *Path) AddDashedStroke(q Path, width geom.Fix32, cr Capper, jr Joiner, dash []geom.Fix32, dashOffset geom.Fix32) {
+	var dashed Path
+	dashPath(&dashed, q, dash, dashOffset)
+	Stroke(p, dashed, width, cr, jr)
+}
+
 // firstgeom.Point returns the first point in a non-empty Path.
 func (p Path) firstPoint() geom.Point {
 	return geom.Pt(p[1], p[2])
@@ -139,32 +148,53 @@ func (p Path) lastPoint() geom.Point {
 	return geom.Pt(p[len(p)-3], p[len(p)-2])
 }
 
+// pathSeg is a single segment yielded by a PathIterator, kept around so that
+// addPathReversed can look back at the segment before it.
+type pathSeg struct {
+	op  Op
+	pts [3]geom.Point
+}
+
+// endPoint returns the point the segment ends at.
+func (s pathSeg) endPoint() geom.Point {
+	switch s.op {
+	case OpStart, OpLine:
+		return s.pts[0]
+	case OpQuad:
+		return s.pts[1]
+	case OpCubic:
+		return s.pts[2]
+	}
+	panic("freetype/raster: bad path")
+}
+
 // addPathReversed adds q reversed to p.
 // For example, if q consists of a linear segment from A to B followed by a
-// quadratic segment from B to C to D, then the values of q looks like:
-// index: 01234567890123
-// value: 0AA01BB12CCDD2
-// So, when adding q backwards to p, we want to Add2(C, B) followed by Add1(A).
+// quadratic segment from B to C to D, then reversing it means we want to
+// Add2(C, B) followed by Add1(A): each segment keeps its own control points
+// (a cubic's two in swapped order), but is replayed backwards, from its own
+// end point to the end point of the segment before it.
 func addPathReversed(p Adder, q Path) {
 	if len(q) == 0 {
 		return
 	}
-	i := len(q) - 1
+	var segs []pathSeg
+	it := q.Iterate()
 	for {
-		switch q[i] {
-		case 0:
-			return
-		case 1:
-			i -= 4
-			p.Add1(geom.Pt(q[i-2], q[i-1]))
-		case 2:
-			i -= 6
-			p.Add2(geom.Pt(q[i+2], q[i+3]), geom.Pt(q[i-2], q[i-1]))
-		case 3:
-			i -= 8
-			p.Add3(geom.Pt(q[i+4], q[i+5]), geom.Pt(q[i+2], q[i+3]), geom.Pt(q[i-2], q[i-1]))
-		default:
-			panic("freetype/raster: bad path")
+		op, pts, ok := it.Next()
+		if !ok {
+			break
+		}
+		segs = append(segs, pathSeg{op, pts})
+	}
+	for i := len(segs) - 1; i > 0; i-- {
+		switch segs[i].op {
+		case OpLine:
+			p.Add1(segs[i-1].endPoint())
+		case OpQuad:
+			p.Add2(segs[i].pts[0], segs[i-1].endPoint())
+		case OpCubic:
+			p.Add3(segs[i].pts[1], segs[i].pts[0], segs[i-1].endPoint())
 		}
 	}
 }
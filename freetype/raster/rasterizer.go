@@ -0,0 +1,111 @@
+// Copyright 2010 The Freetype-Go Authors. All rights reserved.
+// Use of this source code is governed by your choice of either the
+// FreeType License or the GNU General Public License version 2 (or
+// any later version), both of which can be found in the LICENSE file.
+
+package raster
+
+import (
+	"image"
+	"image/color"
+
+	"golang.org/x/image/vector"
+
+	"github.com/eaburns/truefont/freetype/geom"
+)
+
+// A Rasterizer converts a Path, built up via the Adder methods, into a
+// rasterized, antialiased mask. It wraps golang.org/x/image/vector.Rasterizer,
+// translating between Fix32 coordinates and the float32s that package uses.
+type Rasterizer struct {
+	w, h int
+	v    vector.Rasterizer
+}
+
+// NewRasterizer returns a new Rasterizer for rasterizing a path into a mask
+// of the given width and height, in pixels.
+func NewRasterizer(w, h int) *Rasterizer {
+	r := &Rasterizer{w: w, h: h}
+	r.v.Reset(w, h)
+	return r
+}
+
+// f32 converts a Fix32 to the float32 pixel coordinate vector.Rasterizer
+// expects.
+func f32(x geom.Fix32) float32 {
+	return float32(x) / 64
+}
+
+// Start starts a new curve at the given point.
+func (r *Rasterizer) Start(a geom.Point) {
+	r.v.MoveTo(f32(a.X), f32(a.Y))
+}
+
+// Add1 adds a linear segment to the current curve.
+func (r *Rasterizer) Add1(b geom.Point) {
+	r.v.LineTo(f32(b.X), f32(b.Y))
+}
+
+// Add2 adds a quadratic segment to the current curve.
+func (r *Rasterizer) Add2(b, c geom.Point) {
+	r.v.QuadTo(f32(b.X), f32(b.Y), f32(c.X), f32(c.Y))
+}
+
+// Add3 adds a cubic segment to the current curve.
+func (r *Rasterizer) Add3(b, c, d geom.Point) {
+	r.v.CubeTo(f32(b.X), f32(b.Y), f32(c.X), f32(c.Y), f32(d.X), f32(d.Y))
+}
+
+// A Span is a horizontal run of pixels with a constant alpha value, from X0
+// (inclusive) to X1 (exclusive) on row Y.
+type Span struct {
+	Y, X0, X1 int
+	Alpha     uint32
+}
+
+// A Painter receives the Spans produced by rasterizing a Path, one row at a
+// time. done is true on the final call, after all spans (which may be none,
+// for an empty row) have been delivered for that row.
+type Painter interface {
+	Paint(ss []Span, done bool)
+}
+
+// Rasterize accumulates the curves that have been added to r via Start and
+// the AddXxx methods, then paints the resulting spans, row by row, to p.
+func (r *Rasterizer) Rasterize(p Painter) {
+	mask := image.NewAlpha(image.Rect(0, 0, r.w, r.h))
+	r.v.Draw(mask, mask.Bounds(), image.Opaque, image.Point{})
+	for y := 0; y < r.h; y++ {
+		var ss []Span
+		for x := 0; x < r.w; x++ {
+			a := mask.AlphaAt(x, y).A
+			if a == 0 {
+				continue
+			}
+			ss = append(ss, Span{Y: y, X0: x, X1: x + 1, Alpha: uint32(a) * 0x101})
+		}
+		p.Paint(ss, y == r.h-1)
+	}
+}
+
+// alphaSrcPainter paints spans onto an *image.Alpha mask, overwriting each
+// pixel's alpha value rather than blending it with what's already there.
+type alphaSrcPainter struct {
+	m *image.Alpha
+}
+
+// NewAlphaSrcPainter returns a Painter that writes the alpha value of each
+// Span into m, overwriting any existing value.
+func NewAlphaSrcPainter(m *image.Alpha) Painter {
+	return &alphaSrcPainter{m: m}
+}
+
+// Paint implements the Painter interface.
+func (p *alphaSrcPainter) Paint(ss []Span, done bool) {
+	for _, s := range ss {
+		a := uint8(s.Alpha >> 8)
+		for x := s.X0; x < s.X1; x++ {
+			p.m.SetAlpha(x, s.Y, color.Alpha{A: a})
+		}
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2010 The Freetype-Go Authors. All rights reserved.
+// Use of this source code is governed by your choice of either the
+// FreeType License or the GNU General Public License version 2 (or
+// any later version), both of which can be found in the LICENSE file.
+
+package raster
+
+import (
+	"github.com/eaburns/truefont/freetype/geom"
+)
+
+// strokeFlattenTolerance bounds the error introduced by flattening curved
+// segments into a polyline before offsetting them, the same way dashPath
+// flattens curves before measuring arc length.
+const strokeFlattenTolerance = geom.Fix32(4)
+
+// A Capper signifies how to begin or end a stroked path. The Cap method is
+// called with the pen already at pivot+n (the outward offset at one end of
+// an open sub-path) and must leave it at pivot-n.
+type Capper interface {
+	Cap(p Adder, halfWidth geom.Fix32, pivot, n geom.Point)
+}
+
+// A CapperFunc adapts a function to a Capper.
+type CapperFunc func(p Adder, halfWidth geom.Fix32, pivot, n geom.Point)
+
+// Cap calls f.
+func (f CapperFunc) Cap(p Adder, halfWidth geom.Fix32, pivot, n geom.Point) {
+	f(p, halfWidth, pivot, n)
+}
+
+var (
+	// RoundCapper draws a semicircular cap.
+	RoundCapper Capper = CapperFunc(roundCap)
+	// ButtCapper draws a flat cap straight across the end of the path.
+	ButtCapper Capper = CapperFunc(buttCap)
+	// SquareCapper draws a flat cap offset by halfWidth beyond the end of
+	// the path.
+	SquareCapper Capper = CapperFunc(squareCap)
+)
+
+func buttCap(p Adder, halfWidth geom.Fix32, pivot, n geom.Point) {
+	p.Add1(pivot.Sub(n))
+}
+
+func squareCap(p Adder, halfWidth geom.Fix32, pivot, n geom.Point) {
+	m := n.Rot90CCW()
+	p.Add1(pivot.Add(n).Add(m))
+	p.Add1(pivot.Sub(n).Add(m))
+	p.Add1(pivot.Sub(n))
+}
+
+func roundCap(p Adder, halfWidth geom.Fix32, pivot, n geom.Point) {
+	m := n.Rot90CCW()
+	p.Add2(pivot.Add(n).Add(m), pivot.Add(m))
+	p.Add2(pivot.Sub(n).Add(m), pivot.Sub(n))
+}
+
+// A Joiner signifies how to join interior segments of a stroked path. The
+// Join method is called with the pen already at pivot+n0 (the end of the
+// incoming segment's offset line) and must leave it at pivot+n1 (the start
+// of the outgoing segment's offset line).
+type Joiner interface {
+	Join(p Adder, halfWidth geom.Fix32, pivot, n0, n1 geom.Point)
+}
+
+// A JoinerFunc adapts a function to a Joiner.
+type JoinerFunc func(p Adder, halfWidth geom.Fix32, pivot, n0, n1 geom.Point)
+
+// Join calls f.
+func (f JoinerFunc) Join(p Adder, halfWidth geom.Fix32, pivot, n0, n1 geom.Point) {
+	f(p, halfWidth, pivot, n0, n1)
+}
+
+var (
+	// RoundJoiner draws a circular arc between the two segments.
+	RoundJoiner Joiner = JoinerFunc(roundJoin)
+	// BevelJoiner draws a single straight line between the two segments.
+	BevelJoiner Joiner = JoinerFunc(bevelJoin)
+)
+
+func bevelJoin(p Adder, halfWidth geom.Fix32, pivot, n0, n1 geom.Point) {
+	p.Add1(pivot.Add(n1))
+}
+
+func roundJoin(p Adder, halfWidth geom.Fix32, pivot, n0, n1 geom.Point) {
+	p.Add2(pivot.Add(n0).Add(n1), pivot.Add(n1))
+}
+
+// Stroke appends to dst the outline obtained by stroking src with the given
+// width, cap style and join style. Curved segments are first flattened into
+// polylines (each independent sub-path of src is handled separately), then
+// each polyline is offset by halfWidth on both sides and the two offset
+// polylines are joined into a single closed contour via cr and jr.
+func Stroke(dst *Path, src Path, width geom.Fix32, cr Capper, jr Joiner) {
+	if cr == nil {
+		cr = RoundCapper
+	}
+	if jr == nil {
+		jr = RoundJoiner
+	}
+	halfWidth := width / 2
+	for _, sub := range flattenSubpaths(src, strokeFlattenTolerance) {
+		strokeSubpath(dst, sub, halfWidth, cr, jr)
+	}
+}
+
+// flattenSubpaths splits src into its independent sub-paths (each beginning
+// with a Start) and flattens each into a polyline of vertices.
+func flattenSubpaths(src Path, tolerance geom.Fix32) [][]geom.Point {
+	var subs [][]geom.Point
+	var cur []geom.Point
+	var pos geom.Point
+	flush := func() {
+		if len(cur) > 1 {
+			subs = append(subs, cur)
+		}
+		cur = nil
+	}
+	it := src.Iterate()
+	for {
+		op, pts, ok := it.Next()
+		if !ok {
+			break
+		}
+		switch op {
+		case OpStart:
+			flush()
+			pos = pts[0]
+			cur = append(cur, pos)
+		case OpLine:
+			pos = pts[0]
+			cur = append(cur, pos)
+		case OpQuad:
+			for _, pt := range flattenQuadPoints(pos, pts[0], pts[1], tolerance) {
+				cur = append(cur, pt)
+			}
+			pos = pts[1]
+		case OpCubic:
+			for _, pt := range flattenCubicPoints(pos, pts[0], pts[1], pts[2], tolerance) {
+				cur = append(cur, pt)
+			}
+			pos = pts[2]
+		}
+	}
+	flush()
+	return subs
+}
+
+// strokeSubpath appends the stroked outline of the polyline pts to dst. If
+// pts starts and ends at the same point (as any subpath closed with an SVG
+// Z, or any closed glyph contour, does), the two sides of the stroke are
+// emitted as a pair of closed rings joined at every vertex by jr, with no
+// cap; otherwise it's an open polyline, capped at both ends by cr.
+func strokeSubpath(dst *Path, pts []geom.Point, halfWidth geom.Fix32, cr Capper, jr Joiner) {
+	if len(pts) > 2 && pts[0] == pts[len(pts)-1] {
+		strokeClosedSubpath(dst, pts[:len(pts)-1], halfWidth, jr)
+		return
+	}
+	strokeOpenSubpath(dst, pts, halfWidth, cr, jr)
+}
+
+// strokeOpenSubpath appends the stroked outline of the open polyline pts to
+// dst. The outline is a single closed contour: one side of the offset
+// polyline followed immediately by the other side, reversed, so that
+// strokeSide's cap at the far end of the first side exactly continues into
+// strokeSide's (implicit) start for the second.
+func strokeOpenSubpath(dst *Path, pts []geom.Point, halfWidth geom.Fix32, cr Capper, jr Joiner) {
+	n := len(pts)
+	if n < 2 {
+		return
+	}
+	normals := make([]geom.Point, n-1)
+	for i := 0; i < n-1; i++ {
+		normals[i] = pts[i+1].Sub(pts[i]).Rot90CW().Norm(halfWidth)
+	}
+
+	dst.Start(pts[0].Add(normals[0]))
+	strokeSide(dst, pts, normals, halfWidth, cr, jr)
+
+	rPts := make([]geom.Point, n)
+	for k := range rPts {
+		rPts[k] = pts[n-1-k]
+	}
+	rNormals := make([]geom.Point, n-1)
+	for k := range rNormals {
+		rNormals[k] = normals[n-2-k].Neg()
+	}
+	strokeSide(dst, rPts, rNormals, halfWidth, cr, jr)
+}
+
+// strokeClosedSubpath appends the stroked outline of the closed polygon
+// pts (its implicit closing edge runs from pts[len(pts)-1] back to pts[0])
+// to dst, as two separate closed rings: one offset outward, one inward,
+// each joined at every vertex (including the seam at pts[0]) by jr. Unlike
+// an open subpath, neither ring is capped, since a closed contour has no
+// ends.
+func strokeClosedSubpath(dst *Path, pts []geom.Point, halfWidth geom.Fix32, jr Joiner) {
+	m := len(pts)
+	if m < 2 {
+		return
+	}
+	normals := make([]geom.Point, m)
+	for i := range normals {
+		normals[i] = pts[(i+1)%m].Sub(pts[i]).Rot90CW().Norm(halfWidth)
+	}
+	dst.Start(pts[0].Add(normals[0]))
+	strokeRing(dst, pts, normals, halfWidth, jr)
+
+	rPts := make([]geom.Point, m)
+	rNormals := make([]geom.Point, m)
+	for k := range rPts {
+		rPts[k] = pts[m-1-k]
+		rNormals[k] = normals[(2*m-2-k)%m].Neg()
+	}
+	dst.Start(rPts[0].Add(rNormals[0]))
+	strokeRing(dst, rPts, rNormals, halfWidth, jr)
+}
+
+// strokeRing emits one closed ring of an offset polygon: pts[i] offset by
+// normals[i], joined at every vertex (cyclically, so the join after the
+// last vertex connects back to pts[0]+normals[0]) by jr. It assumes the pen
+// is already at pts[0]+normals[0].
+func strokeRing(dst Adder, pts, normals []geom.Point, halfWidth geom.Fix32, jr Joiner) {
+	m := len(pts)
+	for i := 0; i < m; i++ {
+		j := (i + 1) % m
+		dst.Add1(pts[j].Add(normals[i]))
+		jr.Join(dst, halfWidth, pts[j], normals[i], normals[(i+1)%m])
+	}
+}
+
+// strokeSide emits one side of an offset polyline: pts[i] offset by
+// normals[i-1]/normals[i], joined at interior vertices by jr and capped at
+// the far end (pts[len(pts)-1]) by cr. It assumes the pen is already at
+// pts[0]+normals[0], which is either the Start for the first side or the
+// point strokeSide's own cap left the pen at, for the second.
+func strokeSide(dst Adder, pts, normals []geom.Point, halfWidth geom.Fix32, cr Capper, jr Joiner) {
+	n := len(pts)
+	for i := 0; i < n-2; i++ {
+		dst.Add1(pts[i+1].Add(normals[i]))
+		jr.Join(dst, halfWidth, pts[i+1], normals[i], normals[i+1])
+	}
+	dst.Add1(pts[n-1].Add(normals[n-2]))
+	cr.Cap(dst, halfWidth, pts[n-1], normals[n-2])
+}
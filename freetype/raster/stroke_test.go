@@ -0,0 +1,237 @@
+// Copyright 2010 The Freetype-Go Authors. All rights reserved.
+// Use of this source code is governed by your choice of either the
+// FreeType License or the GNU General Public License version 2 (or
+// any later version), both of which can be found in the LICENSE file.
+
+package raster
+
+import (
+	"testing"
+
+	"github.com/eaburns/truefont/freetype/geom"
+)
+
+func TestStrokeHorizontalLineIsClosedRectangle(t *testing.T) {
+	var src Path
+	src.Start(geom.Pt(0, 0))
+	src.Add1(geom.Pt(100*64, 0))
+
+	var dst Path
+	Stroke(&dst, src, 10*64, ButtCapper, BevelJoiner)
+
+	it := dst.Iterate()
+	op, pts, ok := it.Next()
+	if !ok || op != OpStart {
+		t.Fatalf("first op = %v %v %v, want Start", op, pts, ok)
+	}
+	start := pts[0]
+
+	var last geom.Point
+	n := 0
+	for {
+		op, pts, ok = it.Next()
+		if !ok {
+			break
+		}
+		n++
+		switch op {
+		case OpLine:
+			last = pts[0]
+		case OpQuad:
+			last = pts[1]
+		case OpCubic:
+			last = pts[2]
+		}
+	}
+	if n == 0 {
+		t.Fatal("got no segments after Start, want a closed contour")
+	}
+	if last != start {
+		t.Errorf("contour ends at %v, want it to close back to the start %v", last, start)
+	}
+}
+
+func TestStrokeEmptyPathAddsNothing(t *testing.T) {
+	var dst Path
+	Stroke(&dst, Path{}, 10*64, nil, nil)
+	if len(dst) != 0 {
+		t.Errorf("got a %d-element path, want 0", len(dst))
+	}
+}
+
+// ringSegments walks one Start-delimited ring, whose Start has already been
+// read from it. It returns the points the ring visits, and if the ring is
+// followed by another Start (rather than end of path), the point that next
+// Start begins at and true.
+func ringSegments(it *PathIterator) (pts []geom.Point, nextStart geom.Point, hasNext bool) {
+	for {
+		op, p, more := it.Next()
+		if !more {
+			return pts, geom.Point{}, false
+		}
+		if op == OpStart {
+			return pts, p[0], true
+		}
+		switch op {
+		case OpLine:
+			pts = append(pts, p[0])
+		case OpQuad:
+			pts = append(pts, p[1])
+		case OpCubic:
+			pts = append(pts, p[2])
+		}
+	}
+}
+
+func TestStrokeClosedSquareHasNoSeamNotch(t *testing.T) {
+	// A closed 200x200 square, explicitly closed back to its start point,
+	// as SVG's Z command or a closed glyph contour would produce.
+	var src Path
+	src.Start(geom.Pt(0, 0))
+	src.Add1(geom.Pt(200*64, 0))
+	src.Add1(geom.Pt(200*64, 200*64))
+	src.Add1(geom.Pt(0, 200*64))
+	src.Add1(geom.Pt(0, 0))
+
+	var dst Path
+	Stroke(&dst, src, 10*64, ButtCapper, BevelJoiner)
+
+	it := dst.Iterate()
+	op, pts, ok := it.Next()
+	if !ok || op != OpStart {
+		t.Fatalf("first op = %v %v %v, want Start", op, pts, ok)
+	}
+	start1 := pts[0]
+
+	pts1, start2, ok := ringSegments(it)
+	if !ok {
+		t.Fatal("got only one ring, want two (outer and inner)")
+	}
+	// Every one of the 4 corners should be beveled identically: 2 points
+	// per corner (the end of one edge's offset, and the start of the
+	// next's), for 8 points total. If the seam corner were still being
+	// capped instead of joined, this count and the ring's closure would
+	// both be off.
+	if len(pts1) != 8 {
+		t.Errorf("outer ring has %d points, want 8 (4 corners x 2, uniformly beveled)", len(pts1))
+	}
+	if pts1[len(pts1)-1] != start1 {
+		t.Errorf("outer ring ends at %v, want it to close back to its start %v", pts1[len(pts1)-1], start1)
+	}
+
+	pts2, _, ok := ringSegments(it)
+	if ok {
+		t.Fatal("got a third ring, want exactly two")
+	}
+	if len(pts2) != 8 {
+		t.Errorf("inner ring has %d points, want 8 (4 corners x 2, uniformly beveled)", len(pts2))
+	}
+	if pts2[len(pts2)-1] != start2 {
+		t.Errorf("inner ring ends at %v, want it to close back to its start %v", pts2[len(pts2)-1], start2)
+	}
+}
+
+func TestAddStrokePublicEntryPoint(t *testing.T) {
+	var src Path
+	src.Start(geom.Pt(0, 0))
+	src.Add1(geom.Pt(100*64, 0))
+
+	var dst Path
+	dst.AddStroke(src, 10*64, ButtCapper, BevelJoiner)
+	if len(dst) == 0 {
+		t.Fatal("AddStroke appended nothing")
+	}
+
+	op, _, ok := dst.Iterate().Next()
+	if !ok || op != OpStart {
+		t.Fatalf("first op = %v, want Start", op)
+	}
+}
+
+func TestAddDashedStrokePublicEntryPoint(t *testing.T) {
+	var src Path
+	src.Start(geom.Pt(0, 0))
+	src.Add1(geom.Pt(100*64, 0))
+
+	var dst Path
+	dst.AddDashedStroke(src, 10*64, ButtCapper, BevelJoiner, []geom.Fix32{20 * 64, 20 * 64}, 0)
+	if len(dst) == 0 {
+		t.Fatal("AddDashedStroke appended nothing")
+	}
+
+	// A 100px line dashed 20-on/20-off yields 3 on-runs; each is an open
+	// polyline, so each is stroked into a single closed contour: 3 Start
+	// ops in all.
+	it := dst.Iterate()
+	n := 0
+	for {
+		op, _, ok := it.Next()
+		if !ok {
+			break
+		}
+		if op == OpStart {
+			n++
+		}
+	}
+	if n != 3 {
+		t.Errorf("got %d Start ops, want 3 (one per on-run)", n)
+	}
+}
+
+func TestStrokeDefaultCapperAndJoinerAreRound(t *testing.T) {
+	var src Path
+	src.Start(geom.Pt(0, 0))
+	src.Add1(geom.Pt(100*64, 0))
+
+	var dst Path
+	Stroke(&dst, src, 10*64, nil, nil)
+
+	// RoundCapper/RoundJoiner emit Add2 (quadratic) segments for their
+	// curved caps; ButtCapper/BevelJoiner never do. Seeing at least one
+	// confirms the nil defaults resolved to the round variants.
+	it := dst.Iterate()
+	sawCurve := false
+	for {
+		op, _, ok := it.Next()
+		if !ok {
+			break
+		}
+		if op == OpQuad {
+			sawCurve = true
+		}
+	}
+	if !sawCurve {
+		t.Error("got no curved segments from Stroke(nil, nil), want RoundCapper's round cap to emit one")
+	}
+}
+
+func TestSquareCapperExtendsPastTheEndpoint(t *testing.T) {
+	var src Path
+	src.Start(geom.Pt(0, 0))
+	src.Add1(geom.Pt(100*64, 0))
+
+	var dst Path
+	Stroke(&dst, src, 10*64, SquareCapper, BevelJoiner)
+
+	// SquareCapper extends the cap by halfWidth (5px) beyond the line's
+	// end, so some point in the outline should reach all the way to
+	// X=105px; ButtCapper would never pass 100px.
+	const wantPastX = 104 * 64
+	it := dst.Iterate()
+	sawExtension := false
+	for {
+		op, pts, ok := it.Next()
+		if !ok {
+			break
+		}
+		if op == OpStart && pts[0].X > wantPastX {
+			sawExtension = true
+		}
+		if op == OpLine && pts[0].X > wantPastX {
+			sawExtension = true
+		}
+	}
+	if !sawExtension {
+		t.Error("got no point past the squared-off cap extension, want SquareCapper to extend beyond the endpoint")
+	}
+}
@@ -0,0 +1,506 @@
+// Copyright 2010 The Freetype-Go Authors. All rights reserved.
+// Use of this source code is governed by your choice of either the
+// FreeType License or the GNU General Public License version 2 (or
+// any later version), both of which can be found in the LICENSE file.
+
+package raster
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/eaburns/truefont/freetype/geom"
+)
+
+// svgScale is the number of geom.Fix32 units per pixel (the scale of the
+// 26.6 fixed point format), the factor by which SVG path data coordinates
+// are multiplied when converted to a Path.
+const svgScale = 64
+
+// ParseSVGPath parses the path data of an SVG "d" attribute and returns the
+// equivalent Path. It supports the M, L, H, V, Q, T, C, S, Z and A commands
+// in both absolute and relative (lowercase) form, implicit repetition of
+// the last command, whitespace- or comma-separated arguments, and the usual
+// exponential number syntax. Elliptical arcs (A/a) are converted to a
+// sequence of cubic Bézier segments, each spanning at most 90°, via the
+// standard endpoint-to-center parameterization.
+func ParseSVGPath(d string) (Path, error) {
+	p := &svgParser{s: d}
+	var (
+		path     Path
+		started  bool
+		cur      geom.Point
+		subStart geom.Point
+		quadCtl  geom.Point
+		cubicCtl geom.Point
+		// prevFamily is 'Q' or 'C' if the previous segment was a quadratic
+		// or cubic curve, so that T/t and S/s know whether to reflect
+		// quadCtl/cubicCtl or fall back to cur.
+		prevFamily byte
+	)
+	for {
+		p.skipSeparators()
+		if p.i >= len(p.s) {
+			break
+		}
+		cmd := p.s[p.i]
+		if !isSVGCommand(cmd) {
+			return nil, fmt.Errorf("freetype/raster: unexpected character %q at %d in SVG path data", cmd, p.i)
+		}
+		p.i++
+		switch cmd {
+		case 'M', 'm':
+			pt, err := p.point()
+			if err != nil {
+				return nil, err
+			}
+			if cmd == 'm' {
+				pt = cur.Add(pt)
+			}
+			path.Start(pt)
+			cur, subStart, started = pt, pt, true
+			prevFamily = 0
+			for p.moreArgs() {
+				pt, err := p.point()
+				if err != nil {
+					return nil, err
+				}
+				if cmd == 'm' {
+					pt = cur.Add(pt)
+				}
+				path.Add1(pt)
+				cur = pt
+			}
+
+		case 'L', 'l':
+			for first := true; first || p.moreArgs(); first = false {
+				pt, err := p.point()
+				if err != nil {
+					return nil, err
+				}
+				if cmd == 'l' {
+					pt = cur.Add(pt)
+				}
+				path.Add1(pt)
+				cur = pt
+			}
+			prevFamily = 0
+
+		case 'H', 'h':
+			for first := true; first || p.moreArgs(); first = false {
+				x, err := p.number()
+				if err != nil {
+					return nil, err
+				}
+				if cmd == 'h' {
+					x += cur.X
+				}
+				cur = geom.Pt(x, cur.Y)
+				path.Add1(cur)
+			}
+			prevFamily = 0
+
+		case 'V', 'v':
+			for first := true; first || p.moreArgs(); first = false {
+				y, err := p.number()
+				if err != nil {
+					return nil, err
+				}
+				if cmd == 'v' {
+					y += cur.Y
+				}
+				cur = geom.Pt(cur.X, y)
+				path.Add1(cur)
+			}
+			prevFamily = 0
+
+		case 'Q', 'q':
+			for first := true; first || p.moreArgs(); first = false {
+				ctrl, err := p.point()
+				if err != nil {
+					return nil, err
+				}
+				end, err := p.point()
+				if err != nil {
+					return nil, err
+				}
+				if cmd == 'q' {
+					ctrl, end = cur.Add(ctrl), cur.Add(end)
+				}
+				path.Add2(ctrl, end)
+				quadCtl, cur = ctrl, end
+			}
+			prevFamily = 'Q'
+
+		case 'T', 't':
+			for first := true; first || p.moreArgs(); first = false {
+				end, err := p.point()
+				if err != nil {
+					return nil, err
+				}
+				if cmd == 't' {
+					end = cur.Add(end)
+				}
+				ctrl := cur
+				if prevFamily == 'Q' {
+					ctrl = reflect(quadCtl, cur)
+				}
+				path.Add2(ctrl, end)
+				quadCtl, cur = ctrl, end
+				prevFamily = 'Q'
+			}
+
+		case 'C', 'c':
+			for first := true; first || p.moreArgs(); first = false {
+				c1, err := p.point()
+				if err != nil {
+					return nil, err
+				}
+				c2, err := p.point()
+				if err != nil {
+					return nil, err
+				}
+				end, err := p.point()
+				if err != nil {
+					return nil, err
+				}
+				if cmd == 'c' {
+					c1, c2, end = cur.Add(c1), cur.Add(c2), cur.Add(end)
+				}
+				path.Add3(c1, c2, end)
+				cubicCtl, cur = c2, end
+			}
+			prevFamily = 'C'
+
+		case 'S', 's':
+			for first := true; first || p.moreArgs(); first = false {
+				c2, err := p.point()
+				if err != nil {
+					return nil, err
+				}
+				end, err := p.point()
+				if err != nil {
+					return nil, err
+				}
+				if cmd == 's' {
+					c2, end = cur.Add(c2), cur.Add(end)
+				}
+				c1 := cur
+				if prevFamily == 'C' {
+					c1 = reflect(cubicCtl, cur)
+				}
+				path.Add3(c1, c2, end)
+				cubicCtl, cur = c2, end
+				prevFamily = 'C'
+			}
+
+		case 'Z', 'z':
+			path.Add1(subStart)
+			cur = subStart
+			prevFamily = 0
+
+		case 'A', 'a':
+			for first := true; first || p.moreArgs(); first = false {
+				rx, err := p.rawNumber()
+				if err != nil {
+					return nil, err
+				}
+				ry, err := p.rawNumber()
+				if err != nil {
+					return nil, err
+				}
+				rot, err := p.rawNumber()
+				if err != nil {
+					return nil, err
+				}
+				large, err := p.flag()
+				if err != nil {
+					return nil, err
+				}
+				sweep, err := p.flag()
+				if err != nil {
+					return nil, err
+				}
+				end, err := p.point()
+				if err != nil {
+					return nil, err
+				}
+				if cmd == 'a' {
+					end = cur.Add(end)
+				}
+				x0, y0 := float64(cur.X)/svgScale, float64(cur.Y)/svgScale
+				x1, y1 := float64(end.X)/svgScale, float64(end.Y)/svgScale
+				for _, seg := range arcToCubics(x0, y0, rx, ry, rot, large, sweep, x1, y1) {
+					path.Add3(fixPt(seg[0], seg[1]), fixPt(seg[2], seg[3]), fixPt(seg[4], seg[5]))
+				}
+				cur = end
+			}
+			prevFamily = 0
+		}
+	}
+	if !started {
+		return nil, errors.New("freetype/raster: empty SVG path data")
+	}
+	return path, nil
+}
+
+// reflect returns ctrl reflected through about, i.e. 2*about - ctrl, as
+// used by the T/t and S/s commands' implicit control points.
+func reflect(ctrl, about geom.Point) geom.Point {
+	return about.Mul(128).Sub(ctrl)
+}
+
+// fixPt converts a pixel coordinate pair to a geom.Point.
+func fixPt(x, y float64) geom.Point {
+	return geom.Pt(geom.Fix32(math.Round(x*svgScale)), geom.Fix32(math.Round(y*svgScale)))
+}
+
+// arcToCubics converts the SVG elliptical arc from (x0, y0) to (x, y), with
+// radii rx, ry, x-axis rotation xAxisRotDeg (in degrees) and the given
+// large-arc and sweep flags, into a sequence of cubic Bézier segments, each
+// no more than 90° of the ellipse, following the SVG spec's endpoint-to-
+// center parameterization (appendix F.6). Each returned segment is
+// {c1x, c1y, c2x, c2y, ex, ey}.
+func arcToCubics(x0, y0, rx, ry, xAxisRotDeg float64, largeArc, sweep bool, x, y float64) [][6]float64 {
+	if x0 == x && y0 == y {
+		return nil
+	}
+	if rx == 0 || ry == 0 {
+		return [][6]float64{{x0, y0, x, y, x, y}}
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	phi := xAxisRotDeg * math.Pi / 180
+	cosPhi, sinPhi := math.Cos(phi), math.Sin(phi)
+
+	dx2, dy2 := (x0-x)/2, (y0-y)/2
+	x1p := cosPhi*dx2 + sinPhi*dy2
+	y1p := -sinPhi*dx2 + cosPhi*dy2
+
+	if lambda := (x1p*x1p)/(rx*rx) + (y1p*y1p)/(ry*ry); lambda > 1 {
+		s := math.Sqrt(lambda)
+		rx, ry = rx*s, ry*s
+	}
+
+	sign := -1.0
+	if largeArc != sweep {
+		sign = 1.0
+	}
+	num := rx*rx*ry*ry - rx*rx*y1p*y1p - ry*ry*x1p*x1p
+	den := rx*rx*y1p*y1p + ry*ry*x1p*x1p
+	co := 0.0
+	if num > 0 && den > 0 {
+		co = sign * math.Sqrt(num/den)
+	}
+	cxp := co * rx * y1p / ry
+	cyp := -co * ry * x1p / rx
+	cx := cosPhi*cxp - sinPhi*cyp + (x0+x)/2
+	cy := sinPhi*cxp + cosPhi*cyp + (y0+y)/2
+
+	angleBetween := func(ux, uy, vx, vy float64) float64 {
+		length := math.Sqrt((ux*ux + uy*uy) * (vx*vx + vy*vy))
+		cos := 1.0
+		if length != 0 {
+			cos = (ux*vx + uy*vy) / length
+		}
+		cos = math.Max(-1, math.Min(1, cos))
+		a := math.Acos(cos)
+		if ux*vy-uy*vx < 0 {
+			a = -a
+		}
+		return a
+	}
+
+	theta1 := angleBetween(1, 0, (x1p-cxp)/rx, (y1p-cyp)/ry)
+	dtheta := angleBetween((x1p-cxp)/rx, (y1p-cyp)/ry, (-x1p-cxp)/rx, (-y1p-cyp)/ry)
+	if !sweep && dtheta > 0 {
+		dtheta -= 2 * math.Pi
+	} else if sweep && dtheta < 0 {
+		dtheta += 2 * math.Pi
+	}
+
+	n := int(math.Ceil(math.Abs(dtheta) / (math.Pi / 2)))
+	if n < 1 {
+		n = 1
+	}
+	delta := dtheta / float64(n)
+	alpha := 4.0 / 3.0 * math.Tan(delta/4)
+
+	toWorld := func(x, y float64) (float64, float64) {
+		return cx + rx*cosPhi*x - ry*sinPhi*y, cy + rx*sinPhi*x + ry*cosPhi*y
+	}
+
+	segs := make([][6]float64, n)
+	theta := theta1
+	for i := 0; i < n; i++ {
+		t0, t1 := theta, theta+delta
+		cos0, sin0 := math.Cos(t0), math.Sin(t0)
+		cos1, sin1 := math.Cos(t1), math.Sin(t1)
+		c1x, c1y := toWorld(cos0-alpha*sin0, sin0+alpha*cos0)
+		c2x, c2y := toWorld(cos1+alpha*sin1, sin1-alpha*cos1)
+		ex, ey := toWorld(cos1, sin1)
+		segs[i] = [6]float64{c1x, c1y, c2x, c2y, ex, ey}
+		theta = t1
+	}
+	return segs
+}
+
+// isSVGCommand reports whether c is one of the path data command letters
+// supported by ParseSVGPath.
+func isSVGCommand(c byte) bool {
+	switch c {
+	case 'M', 'm', 'L', 'l', 'H', 'h', 'V', 'v', 'Q', 'q', 'T', 't', 'C', 'c', 'S', 's', 'Z', 'z', 'A', 'a':
+		return true
+	}
+	return false
+}
+
+// isNumberStart reports whether c could begin an SVG number.
+func isNumberStart(c byte) bool {
+	return c == '+' || c == '-' || c == '.' || ('0' <= c && c <= '9')
+}
+
+// svgParser scans the path data grammar: commands, numbers and flags
+// separated by optional whitespace and commas.
+type svgParser struct {
+	s string
+	i int
+}
+
+func (p *svgParser) skipSeparators() {
+	for p.i < len(p.s) {
+		switch p.s[p.i] {
+		case ' ', '\t', '\r', '\n', ',':
+			p.i++
+		default:
+			return
+		}
+	}
+}
+
+// moreArgs reports whether another argument for the current, possibly
+// implicitly repeated, command follows.
+func (p *svgParser) moreArgs() bool {
+	p.skipSeparators()
+	return p.i < len(p.s) && isNumberStart(p.s[p.i])
+}
+
+// scanNumber scans a single number (with an optional sign, fractional part
+// and exponent) and returns its source text.
+func (p *svgParser) scanNumber() (string, error) {
+	p.skipSeparators()
+	start := p.i
+	i := p.i
+	if i < len(p.s) && (p.s[i] == '+' || p.s[i] == '-') {
+		i++
+	}
+	for i < len(p.s) && '0' <= p.s[i] && p.s[i] <= '9' {
+		i++
+	}
+	if i < len(p.s) && p.s[i] == '.' {
+		i++
+		for i < len(p.s) && '0' <= p.s[i] && p.s[i] <= '9' {
+			i++
+		}
+	}
+	if i < len(p.s) && (p.s[i] == 'e' || p.s[i] == 'E') {
+		j := i + 1
+		if j < len(p.s) && (p.s[j] == '+' || p.s[j] == '-') {
+			j++
+		}
+		if j < len(p.s) && '0' <= p.s[j] && p.s[j] <= '9' {
+			for j < len(p.s) && '0' <= p.s[j] && p.s[j] <= '9' {
+				j++
+			}
+			i = j
+		}
+	}
+	if i == start {
+		return "", fmt.Errorf("freetype/raster: expected a number at %d in SVG path data", start)
+	}
+	p.i = i
+	return p.s[start:i], nil
+}
+
+// rawNumber scans a number and returns it as an unscaled float64, for
+// arguments (e.g. an arc's x-axis-rotation) that are not pixel coordinates.
+func (p *svgParser) rawNumber() (float64, error) {
+	tok, err := p.scanNumber()
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(tok, 64)
+	if err != nil {
+		return 0, fmt.Errorf("freetype/raster: invalid number %q in SVG path data", tok)
+	}
+	return f, nil
+}
+
+// number scans a number and returns it as a geom.Fix32 pixel coordinate.
+func (p *svgParser) number() (geom.Fix32, error) {
+	f, err := p.rawNumber()
+	if err != nil {
+		return 0, err
+	}
+	return geom.Fix32(math.Round(f * svgScale)), nil
+}
+
+// point scans an x, y coordinate pair.
+func (p *svgParser) point() (geom.Point, error) {
+	x, err := p.number()
+	if err != nil {
+		return geom.Point{}, err
+	}
+	y, err := p.number()
+	if err != nil {
+		return geom.Point{}, err
+	}
+	return geom.Pt(x, y), nil
+}
+
+// flag scans a single SVG flag argument: a bare '0' or '1' digit, as used
+// by the large-arc and sweep arguments of the A/a command.
+func (p *svgParser) flag() (bool, error) {
+	p.skipSeparators()
+	if p.i >= len(p.s) || (p.s[p.i] != '0' && p.s[p.i] != '1') {
+		return false, fmt.Errorf("freetype/raster: expected a flag (0 or 1) at %d in SVG path data", p.i)
+	}
+	v := p.s[p.i] == '1'
+	p.i++
+	return v, nil
+}
+
+// SVGPathData returns p as SVG path data (the contents of a "d" attribute),
+// using absolute M/L/Q/C commands, so that it round-trips through
+// ParseSVGPath. Each Start in p begins a new, unclosed subpath.
+func (p Path) SVGPathData() string {
+	var b strings.Builder
+	it := p.Iterate()
+	for first := true; ; first = false {
+		op, pts, ok := it.Next()
+		if !ok {
+			break
+		}
+		if !first {
+			b.WriteByte(' ')
+		}
+		switch op {
+		case OpStart:
+			fmt.Fprintf(&b, "M%s,%s", svgNum(pts[0].X), svgNum(pts[0].Y))
+		case OpLine:
+			fmt.Fprintf(&b, "L%s,%s", svgNum(pts[0].X), svgNum(pts[0].Y))
+		case OpQuad:
+			fmt.Fprintf(&b, "Q%s,%s %s,%s", svgNum(pts[0].X), svgNum(pts[0].Y), svgNum(pts[1].X), svgNum(pts[1].Y))
+		case OpCubic:
+			fmt.Fprintf(&b, "C%s,%s %s,%s %s,%s", svgNum(pts[0].X), svgNum(pts[0].Y), svgNum(pts[1].X), svgNum(pts[1].Y), svgNum(pts[2].X), svgNum(pts[2].Y))
+		}
+	}
+	return b.String()
+}
+
+// svgNum formats a Fix32 coordinate as a plain decimal number of pixels.
+func svgNum(x geom.Fix32) string {
+	return strconv.FormatFloat(float64(x)/svgScale, 'g', -1, 64)
+}
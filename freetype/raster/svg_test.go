@@ -0,0 +1,99 @@
+// Copyright 2010 The Freetype-Go Authors. All rights reserved.
+// Use of this source code is governed by your choice of either the
+// FreeType License or the GNU General Public License version 2 (or
+// any later version), both of which can be found in the LICENSE file.
+
+package raster
+
+import (
+	"testing"
+
+	"github.com/eaburns/truefont/freetype/geom"
+)
+
+func TestSVGPathDataRoundTrip(t *testing.T) {
+	tests := []string{
+		"M0,0 L10,0 L10,10 L0,10",
+		"M0,0 Q5,10 10,0",
+		"M0,0 C0,10 10,10 10,0",
+		"M1,2 L3,4 Q5,6 7,8 C9,10 11,12 13,14",
+	}
+	for _, d := range tests {
+		p, err := ParseSVGPath(d)
+		if err != nil {
+			t.Errorf("ParseSVGPath(%q): %v", d, err)
+			continue
+		}
+		got := p.SVGPathData()
+		q, err := ParseSVGPath(got)
+		if err != nil {
+			t.Errorf("ParseSVGPath(%q) (round-tripped from %q): %v", got, d, err)
+			continue
+		}
+		if len(p) != len(q) {
+			t.Errorf("round-trip of %q through %q changed the path: got %v, want %v", d, got, q, p)
+			continue
+		}
+		for i := range p {
+			if p[i] != q[i] {
+				t.Errorf("round-trip of %q through %q changed the path: got %v, want %v", d, got, q, p)
+				break
+			}
+		}
+	}
+}
+
+func TestSVGPathDataMultipleSubpaths(t *testing.T) {
+	var p Path
+	p.Start(geom.Pt(0, 0))
+	p.Add1(geom.Pt(10*64, 0))
+	p.Start(geom.Pt(20*64, 0))
+	p.Add1(geom.Pt(30*64, 0))
+
+	const want = "M0,0 L10,0 M20,0 L30,0"
+	if got := p.SVGPathData(); got != want {
+		t.Errorf("SVGPathData() = %q, want %q", got, want)
+	}
+}
+
+func TestParseSVGPathArcIsApproximatedByCubics(t *testing.T) {
+	// A quarter-circle arc of radius 10 from (10,0) to (0,10), centered at
+	// the origin.
+	p, err := ParseSVGPath("M10,0 A10,10 0 0 1 0,10")
+	if err != nil {
+		t.Fatalf("ParseSVGPath: %v", err)
+	}
+
+	const center, radius = 0.0, 10.0
+	const tolerance = 0.5 // pixels; arcToCubics is an approximation.
+
+	it := p.Iterate()
+	op, pts, ok := it.Next()
+	if !ok || op != OpStart {
+		t.Fatalf("first op = %v %v %v, want Start", op, pts, ok)
+	}
+	n := 0
+	for {
+		op, pts, ok = it.Next()
+		if !ok {
+			break
+		}
+		if op != OpCubic {
+			t.Fatalf("op = %v, want every arc segment to be a cubic", op)
+		}
+		n++
+		// The end point of each cubic segment lies on the arc itself (the
+		// control points bulge outward to approximate the curvature, so
+		// only the endpoint is expected to sit on the circle).
+		pt := pts[2]
+		x, y := float64(pt.X)/svgScale-center, float64(pt.Y)/svgScale-center
+		dist := x*x + y*y
+		got := dist - radius*radius
+		if got < -radius*tolerance*2 || got > radius*tolerance*2 {
+			t.Errorf("end point %v is not close to the radius-%v circle", pt, radius)
+		}
+	}
+	if n == 0 {
+		t.Fatal("got no cubic segments for the arc")
+	}
+}